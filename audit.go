@@ -0,0 +1,193 @@
+package mongoutils
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/mgo.v2"
+)
+
+const (
+	AUDIT_CHANNEL_BUFFER_SIZE = 100
+)
+
+//ERROR MESSAGES
+var ErrAuditAlreadyEnabled = errors.New("auditAlreadyEnabled")
+
+//context key type so SetAuditActor/auditActor don't collide with other packages' context values
+type auditContextKey int
+
+const auditActorKey auditContextKey = 0
+
+//auditEntry IS ONE DOCUMENT WRITTEN TO THE CAPPED AUDIT COLLECTION
+type auditEntry struct {
+	Timestamp     time.Time   `bson:"ts"`
+	Op            string      `bson:"op"`
+	Namespace     string      `bson:"ns"`
+	Selector      interface{} `bson:"selector,omitempty"`
+	ChangeSummary interface{} `bson:"changeSummary,omitempty"`
+	Actor         string      `bson:"actor,omitempty"`
+}
+
+//global audit state, set up by EnableAudit
+var (
+	auditMu      sync.Mutex
+	auditEnabled bool
+	auditDb      string
+	auditColl    string
+	auditEntries chan auditEntry
+)
+
+//SetAuditActor ATTACHES THE ACTING USER TO A CONTEXT SO AuditInsert/AuditUpdate/AuditRemove CAN RECORD WHO MADE A CHANGE
+//pull the user out of the request (ex: a session cookie or auth middleware) and pass it down with this before calling
+//the Audit* wrapper functions
+//in: context, username/actor identifier
+//out: a new context carrying the actor
+func SetAuditActor(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, auditActorKey, user)
+}
+
+//GET THE ACTOR PREVIOUSLY ATTACHED BY SetAuditActor, OR "" IF NONE WAS SET
+func auditActor(ctx context.Context) string {
+	actor, _ := ctx.Value(auditActorKey).(string)
+	return actor
+}
+
+//EnableAudit TURNS ON THE AUDIT SUBSYSTEM
+//creates a capped collection (if it does not already exist) to hold audit documents and starts the background
+//goroutine that drains writes from a buffered channel; AuditInsert/AuditUpdate/AuditRemove queue onto that channel
+//without waiting for the audit write itself, dropping (and logging) entries if the buffer is ever full
+//can only be called once per process - call it during startup, not on every reload, since there is no matching
+//Disable to stop the existing background goroutine first
+//in: db the capped collection lives in, collection name, max size of the capped collection in bytes
+//out: ErrAuditAlreadyEnabled if audit was already turned on, or an error if the capped collection could not be created
+func EnableAudit(db string, coll string, sizeBytes int64) error {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	if auditEnabled {
+		return ErrAuditAlreadyEnabled
+	}
+
+	session := SESSION.Copy()
+	defer session.Close()
+
+	err := session.DB(db).C(coll).Create(&mgo.CollectionInfo{
+		Capped:   true,
+		MaxBytes: sizeBytes,
+	})
+	if err != nil && !isNamespaceExists(err) {
+		return err
+	}
+
+	auditDb = db
+	auditColl = coll
+	auditEntries = make(chan auditEntry, AUDIT_CHANNEL_BUFFER_SIZE)
+	auditEnabled = true
+
+	go runAuditWriter()
+
+	return nil
+}
+
+//CHECK WHETHER A Create ERROR IS JUST MONGO SAYING THE COLLECTION ALREADY EXISTS (CODE 48, "NamespaceExists")
+//this is expected every time EnableAudit runs after the first time (ex: on each app restart) and is not a real failure
+//mgo surfaces this as a *mgo.QueryError rather than a duplicate-key error, so mgo.IsDup does not catch it
+func isNamespaceExists(err error) bool {
+	if queryErr, ok := err.(*mgo.QueryError); ok {
+		return queryErr.Code == 48
+	}
+
+	return strings.Contains(err.Error(), "already exists")
+}
+
+//BACKGROUND GOROUTINE THAT WRITES QUEUED AUDIT ENTRIES TO THE CAPPED COLLECTION
+//runs for the lifetime of the process once EnableAudit is called
+func runAuditWriter() {
+	session := SESSION.Copy()
+	defer session.Close()
+
+	coll := session.DB(auditDb).C(auditColl)
+
+	for entry := range auditEntries {
+		if err := coll.Insert(entry); err != nil {
+			log.Println("audit.go-runAuditWriter error", err)
+		}
+	}
+}
+
+//QUEUE AN AUDIT DOCUMENT FOR THE BACKGROUND WRITER
+//never blocks the caller: if the buffered channel is full (ex: the audit collection/DB is slow or down) the entry
+//is dropped and logged rather than stalling the write path that called AuditInsert/AuditUpdate/AuditRemove
+func enqueueAudit(ctx context.Context, op string, db string, coll string, selector interface{}, changeSummary interface{}) {
+	if auditEntries == nil {
+		return
+	}
+
+	entry := auditEntry{
+		Timestamp:     time.Now(),
+		Op:            op,
+		Namespace:     db + "." + coll,
+		Selector:      selector,
+		ChangeSummary: changeSummary,
+		Actor:         auditActor(ctx),
+	}
+
+	select {
+	case auditEntries <- entry:
+	default:
+		log.Println("audit.go-enqueueAudit error: audit channel full, dropping entry for", entry.Namespace)
+	}
+}
+
+//AuditInsert INSERTS doc INTO db.coll AND, ON SUCCESS, QUEUES AN AUDIT RECORD OF THE INSERT
+//in: context (carrying the actor set by SetAuditActor), db, collection, document to insert
+//out: error from the insert itself
+func AuditInsert(ctx context.Context, db string, coll string, doc interface{}) error {
+	session := SESSION.Copy()
+	defer session.Close()
+
+	err := session.DB(db).C(coll).Insert(doc)
+	if err != nil {
+		return err
+	}
+
+	enqueueAudit(ctx, "insert", db, coll, nil, doc)
+	return nil
+}
+
+//AuditUpdate UPDATES A DOCUMENT MATCHING selector IN db.coll AND, ON SUCCESS, QUEUES AN AUDIT RECORD OF THE UPDATE
+//in: context (carrying the actor set by SetAuditActor), db, collection, selector, update
+//out: error from the update itself
+func AuditUpdate(ctx context.Context, db string, coll string, selector interface{}, update interface{}) error {
+	session := SESSION.Copy()
+	defer session.Close()
+
+	err := session.DB(db).C(coll).Update(selector, update)
+	if err != nil {
+		return err
+	}
+
+	enqueueAudit(ctx, "update", db, coll, selector, update)
+	return nil
+}
+
+//AuditRemove REMOVES A DOCUMENT MATCHING selector FROM db.coll AND, ON SUCCESS, QUEUES AN AUDIT RECORD OF THE REMOVE
+//in: context (carrying the actor set by SetAuditActor), db, collection, selector
+//out: error from the remove itself
+func AuditRemove(ctx context.Context, db string, coll string, selector interface{}) error {
+	session := SESSION.Copy()
+	defer session.Close()
+
+	err := session.DB(db).C(coll).Remove(selector)
+	if err != nil {
+		return err
+	}
+
+	enqueueAudit(ctx, "remove", db, coll, selector, nil)
+	return nil
+}
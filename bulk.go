@@ -0,0 +1,126 @@
+package mongoutils
+
+import (
+	"fmt"
+
+	"gopkg.in/mgo.v2"
+)
+
+//*********************************************************************************************************************************
+//BULK WRITES
+
+//BulkWriter BATCHES UP INSERTS, UPSERTS, AND REMOVES AND FLUSHES THEM TO MONGO IN BOUNDED-SIZE GROUPS
+//this gives a bounded-memory way to load many documents instead of issuing one round trip per document
+type BulkWriter struct {
+	session   *mgo.Session
+	bulk      *mgo.Bulk
+	coll      *mgo.Collection
+	ordered   bool
+	batchSize int
+	pending   int
+	Errors    []error
+}
+
+//BulkWriteError AGGREGATES THE ERRORS FROM EVERY BATCH FLUSHED BY A BulkWriter
+type BulkWriteError struct {
+	Errors []error
+}
+
+func (b *BulkWriteError) Error() string {
+	return fmt.Sprintf("mongoutils: %d batch(es) failed, first error: %v", len(b.Errors), b.Errors[0])
+}
+
+//NEW BULK WRITER
+//copies the global SESSION so this BulkWriter does not block other callers (per mgo pooling documents)
+//ordered stops the batch on the first error (matches mgo's Bulk.Unordered() default of ordered)
+//batchSize is how many ops accumulate before Flush() is called automatically, pass 0 to never auto-flush
+//in: db name, collection name, ordered mode, batchSize
+//out: a ready-to-use BulkWriter
+func NewBulkWriter(db string, coll string, ordered bool, batchSize int) *BulkWriter {
+	session := SESSION.Copy()
+	collection := session.DB(db).C(coll)
+	bulk := collection.Bulk()
+
+	if !ordered {
+		bulk.Unordered()
+	}
+
+	return &BulkWriter{
+		session:   session,
+		bulk:      bulk,
+		coll:      collection,
+		ordered:   ordered,
+		batchSize: batchSize,
+	}
+}
+
+//QUEUE DOCUMENTS TO BE INSERTED
+//auto-flushes once the batchSize is reached
+func (b *BulkWriter) Insert(docs ...interface{}) error {
+	b.bulk.Insert(docs...)
+	b.pending += len(docs)
+
+	return b.autoFlush()
+}
+
+//QUEUE AN UPSERT
+//auto-flushes once the batchSize is reached
+func (b *BulkWriter) Upsert(selector interface{}, update interface{}) error {
+	b.bulk.Upsert(selector, update)
+	b.pending++
+
+	return b.autoFlush()
+}
+
+//QUEUE A REMOVE
+//auto-flushes once the batchSize is reached
+func (b *BulkWriter) Remove(selector interface{}) error {
+	b.bulk.Remove(selector)
+	b.pending++
+
+	return b.autoFlush()
+}
+
+//FLUSH THE CURRENT BATCH IF IT HAS REACHED batchSize
+func (b *BulkWriter) autoFlush() error {
+	if b.batchSize <= 0 || b.pending < b.batchSize {
+		return nil
+	}
+
+	_, err := b.Flush()
+	return err
+}
+
+//FLUSH QUEUED OPS TO MONGO
+//runs the accumulated ops, starts a new batch for any further ops, and records the error (if any) on Errors
+//in: -
+//out: the result of this batch and, only if this batch failed, a *BulkWriteError aggregating this and any prior failed
+//batches; nil if this batch succeeded or nothing was queued, even when earlier batches failed (see Errors)
+func (b *BulkWriter) Flush() (*mgo.BulkResult, error) {
+	if b.pending == 0 {
+		return nil, nil
+	}
+
+	result, err := b.bulk.Run()
+	b.pending = 0
+	b.bulk = b.coll.Bulk()
+	if !b.ordered {
+		b.bulk.Unordered()
+	}
+
+	if err != nil {
+		b.Errors = append(b.Errors, err)
+		return result, &BulkWriteError{Errors: b.Errors}
+	}
+
+	return result, nil
+}
+
+//CLOSE THE SESSION THIS BulkWriter OPENED
+//flushes any remaining queued ops first
+func (b *BulkWriter) Close() error {
+	_, err := b.Flush()
+	b.session.Close()
+
+	return err
+}
@@ -0,0 +1,102 @@
+package mongoutils
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+
+	"gopkg.in/mgo.v2"
+)
+
+//AUTH MECHANISMS SUPPORTED BY Config.AuthMechanism
+//these map directly to mgo's DialInfo.Mechanism values
+const (
+	AUTH_MECHANISM_SCRAM_SHA1 = "SCRAM-SHA-1"
+	AUTH_MECHANISM_X509       = "MONGODB-X509"
+	AUTH_MECHANISM_PLAIN      = "PLAIN"
+)
+
+//Config HOLDS EVERYTHING NEEDED TO DIAL A MONGODB SERVER OR REPLICA SET
+//this supersedes the server/database/readPreference/writeConcern arguments Connect() takes since it predates mgo's
+//full mongodb:// URI support
+type Config struct {
+	//URI is a full "mongodb://" connection string, parsed with mgo.ParseURL
+	//it may include servers, the default database, and query-string options (replicaSet, authSource, etc)
+	URI string
+
+	//ReadPreference is an mgo consistency constant (Eventual, Monotonic, Strong), 0 (Eventual) is used if unset
+	ReadPreference int
+
+	//WriteConcern is an mgo *Safe type, nil disables write acknowledgement
+	WriteConcern *mgo.Safe
+
+	//TLSConfig enables TLS when non-nil, DialServer is wired up to dial through tls.DialWithDialer
+	TLSConfig *tls.Config
+
+	//AuthMechanism selects SCRAM/X509/PLAIN auth, leave empty to use whatever AuthMechanism mgo picks from the URI
+	AuthMechanism string
+
+	//ConnectTimeout, SocketTimeout caps dialing and per-socket reads/writes, 0 uses mgo's defaults
+	ConnectTimeout time.Duration
+	SocketTimeout  time.Duration
+
+	//SyncTimeout caps how long mgo waits for a topology sync before failing a connection attempt, 0 uses mgo's default
+	SyncTimeout time.Duration
+
+	//MaxPoolSize bounds how many sockets mgo keeps open per server (mgo.DialInfo.PoolLimit), 0 leaves mgo's default pool limit
+	//mgo has no minimum pool size knob, so there is no MinPoolSize here
+	MaxPoolSize int
+
+	//AppName is reported to the server for logging/diagnostics (mgo.DialInfo.AppName)
+	AppName string
+}
+
+//CONNECT TO DB USING A FULL Config INSTEAD OF THE LEGACY Connect ARGUMENTS
+//parses cfg.URI with mgo.ParseURL, applies TLS/auth/timeout/pool settings to the resulting DialInfo, and dials
+//unlike Connect, this does not touch the global SESSION variable so callers can hold multiple named sessions
+//in: Config
+//out: a connected, ready-to-use *mgo.Session
+func ConnectWithConfig(cfg Config) (*mgo.Session, error) {
+	dialInfo, err := mgo.ParseURL(cfg.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ConnectTimeout > 0 {
+		dialInfo.Timeout = cfg.ConnectTimeout
+	}
+	if cfg.SocketTimeout > 0 {
+		dialInfo.ReadTimeout = cfg.SocketTimeout
+		dialInfo.WriteTimeout = cfg.SocketTimeout
+	}
+	if cfg.MaxPoolSize > 0 {
+		dialInfo.PoolLimit = cfg.MaxPoolSize
+	}
+	if cfg.AppName != "" {
+		dialInfo.AppName = cfg.AppName
+	}
+	if cfg.AuthMechanism != "" {
+		dialInfo.Mechanism = cfg.AuthMechanism
+	}
+
+	if cfg.TLSConfig != nil {
+		dialer := &net.Dialer{Timeout: cfg.ConnectTimeout}
+		dialInfo.DialServer = func(addr *mgo.ServerAddr) (net.Conn, error) {
+			return tls.DialWithDialer(dialer, "tcp", addr.String(), cfg.TLSConfig)
+		}
+	}
+
+	session, err := mgo.DialWithInfo(dialInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.SyncTimeout > 0 {
+		session.SetSyncTimeout(cfg.SyncTimeout)
+	}
+
+	setReadPreference(session, cfg.ReadPreference)
+	session.SetSafe(cfg.WriteConcern)
+
+	return session, nil
+}
@@ -0,0 +1,137 @@
+package mongoutils
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+//*********************************************************************************************************************************
+//GRIDFS
+
+//PUT A FILE INTO GRIDFS
+//copies the global SESSION so this call does not block other callers (per mgo pooling documents)
+//bucket is the gridfs prefix (files are stored in <bucket>.files and <bucket>.chunks)
+//filename is the name saved with the file, contentType is stored on the file and is what ServeFile later emits as
+//Content-Type, meta is optional extra data stored on the file's "metadata" field
+//in: bucket, filename, contentType, a reader of the file's contents, metadata
+//out: the objectId the file was stored under, error if the upload failed
+func PutFile(bucket string, filename string, contentType string, r io.Reader, meta bson.M) (bson.ObjectId, error) {
+	session := SESSION.Copy()
+	defer session.Close()
+
+	id := bson.NewObjectId()
+
+	if err := putFileWithId(session, bucket, id, filename, contentType, r, meta); err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+//PUT A FILE INTO GRIDFS USING A CALLER-PROVIDED ID
+//some ecosystem tools need to control the _id of a gridfs file (ex: keeping it in sync with another collection) instead of
+//always letting mongo generate one, so this lets the caller pass their own id
+//in: bucket, the _id to store the file under, filename, contentType, a reader of the file's contents, metadata
+//out: error if the upload failed
+func PutFileWithId(bucket string, id bson.ObjectId, filename string, contentType string, r io.Reader, meta bson.M) error {
+	session := SESSION.Copy()
+	defer session.Close()
+
+	return putFileWithId(session, bucket, id, filename, contentType, r, meta)
+}
+
+//SHARED IMPLEMENTATION FOR PUTTING A FILE SO PutFile AND PutFileWithId DO NOT DUPLICATE THE GRIDFS CALLS
+func putFileWithId(session *mgo.Session, bucket string, id bson.ObjectId, filename string, contentType string, r io.Reader, meta bson.M) error {
+	gridFile, err := session.DB("").GridFS(bucket).Create(filename)
+	if err != nil {
+		return err
+	}
+
+	gridFile.SetId(id)
+	if contentType != "" {
+		gridFile.SetContentType(contentType)
+	}
+	if meta != nil {
+		gridFile.SetMeta(meta)
+	}
+
+	if _, err := io.Copy(gridFile, r); err != nil {
+		gridFile.Close()
+		return err
+	}
+
+	return gridFile.Close()
+}
+
+//GET A FILE OUT OF GRIDFS
+//id is the hex string of the file's _id
+//writes the file's contents to w
+//in: bucket, id as a hex string, writer to copy the file's contents into
+//out: error if the id is invalid or the file could not be read
+func GetFile(bucket string, id string, w io.Writer) error {
+	objId, err := GetObjectIdFromString(id)
+	if err != nil {
+		return err
+	}
+
+	session := SESSION.Copy()
+	defer session.Close()
+
+	gridFile, err := session.DB("").GridFS(bucket).OpenId(objId)
+	if err != nil {
+		return err
+	}
+	defer gridFile.Close()
+
+	_, err = io.Copy(w, gridFile)
+	return err
+}
+
+//DELETE A FILE FROM GRIDFS
+//removes both the file entry and its chunks
+//in: bucket, id as a hex string
+//out: error if the id is invalid or the file could not be removed
+func DeleteFile(bucket string, id string) error {
+	objId, err := GetObjectIdFromString(id)
+	if err != nil {
+		return err
+	}
+
+	session := SESSION.Copy()
+	defer session.Close()
+
+	return session.DB("").GridFS(bucket).RemoveId(objId)
+}
+
+//SERVE A FILE OUT OF GRIDFS OVER HTTP
+//sets Content-Type and Content-Length from the gridfs file and honors a "Range" request header for partial content
+//expects the request's "id" form value to hold the file's hex id (ex: example.com/download?id=...)
+//in: bucket, http response writer, http request
+func ServeFile(bucket string, w http.ResponseWriter, r *http.Request) {
+	id := r.FormValue("id")
+
+	objId, err := GetObjectIdFromString(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	session := SESSION.Copy()
+	defer session.Close()
+
+	gridFile, err := session.DB("").GridFS(bucket).OpenId(objId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer gridFile.Close()
+
+	w.Header().Set("Content-Type", gridFile.ContentType())
+	w.Header().Set("Accept-Ranges", "bytes")
+
+	http.ServeContent(w, r, gridFile.Name(), time.Time{}, gridFile)
+}
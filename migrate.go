@@ -0,0 +1,177 @@
+package mongoutils
+
+import (
+	"errors"
+	"sort"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	MIGRATIONS_COLLECTION = "schema_migrations"
+	MIGRATIONS_LOCK_ID    = "lock"
+)
+
+var (
+	//ERROR MESSAGES
+	ErrMigrationLocked   = errors.New("migrationsAlreadyRunning")
+	ErrMigrationNotFound = errors.New("migrationVersionNotFound")
+)
+
+//*********************************************************************************************************************************
+//SCHEMA MIGRATIONS
+
+//Migration IS A SINGLE SCHEMA CHANGE A Runner CAN APPLY OR ROLL BACK
+type Migration interface {
+	//Version RETURNS A UNIQUE, SORTABLE IDENTIFIER FOR THIS MIGRATION (ex: "2018-01-02-001")
+	Version() string
+
+	//Up APPLIES THE MIGRATION
+	Up(*mgo.Session) error
+
+	//Down REVERSES THE MIGRATION
+	Down(*mgo.Session) error
+}
+
+//Runner APPLIES A LIST OF Migrations AND TRACKS WHICH ONES HAVE ALREADY RUN IN THE schema_migrations COLLECTION
+type Runner struct {
+	db         string
+	migrations []Migration
+}
+
+//record of an already-applied migration, stored one per document in MIGRATIONS_COLLECTION
+type migrationRecord struct {
+	Id string `bson:"_id"`
+}
+
+//NEW MIGRATION RUNNER
+//db is the database the schema_migrations collection (and the lock document) live in
+//migrations does not need to be pre-sorted, NewRunner sorts a copy by Version() so Run() applies them in order
+//in: db name, list of migrations
+//out: a ready-to-use Runner
+func NewRunner(db string, migrations []Migration) *Runner {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version() < sorted[j].Version()
+	})
+
+	return &Runner{db: db, migrations: sorted}
+}
+
+//RUN ALL PENDING MIGRATIONS IN ORDER
+//takes out an advisory lock (an insert-with-unique-_id on the lock document) so two runners cannot apply migrations at
+//the same time; the lock is released (removed) once this call returns
+//in: -
+//out: error if a migration failed, the lock was already held, or the lock/record bookkeeping failed
+func (r *Runner) Run() error {
+	session := SESSION.Copy()
+	defer session.Close()
+
+	if err := r.lock(session); err != nil {
+		return err
+	}
+	defer r.unlock(session)
+
+	applied, err := r.appliedVersions(session)
+	if err != nil {
+		return err
+	}
+
+	coll := session.DB(r.db).C(MIGRATIONS_COLLECTION)
+
+	for _, m := range r.migrations {
+		if applied[m.Version()] {
+			continue
+		}
+
+		if err := m.Up(session); err != nil {
+			return err
+		}
+
+		if err := coll.Insert(migrationRecord{Id: m.Version()}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//Rollback UNDOES MIGRATIONS DOWN TO (BUT NOT INCLUDING) target, IN REVERSE ORDER
+//target must be the Version() of a migration that has already been applied
+//in: version to roll back to
+//out: error if target was never applied, a Down() failed, or the lock/record bookkeeping failed
+func (r *Runner) Rollback(target string) error {
+	session := SESSION.Copy()
+	defer session.Close()
+
+	if err := r.lock(session); err != nil {
+		return err
+	}
+	defer r.unlock(session)
+
+	applied, err := r.appliedVersions(session)
+	if err != nil {
+		return err
+	}
+	if !applied[target] {
+		return ErrMigrationNotFound
+	}
+
+	coll := session.DB(r.db).C(MIGRATIONS_COLLECTION)
+
+	for i := len(r.migrations) - 1; i >= 0; i-- {
+		m := r.migrations[i]
+		if m.Version() <= target || !applied[m.Version()] {
+			continue
+		}
+
+		if err := m.Down(session); err != nil {
+			return err
+		}
+
+		if err := coll.RemoveId(m.Version()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//GET THE SET OF VERSIONS ALREADY RECORDED AS APPLIED
+func (r *Runner) appliedVersions(session *mgo.Session) (map[string]bool, error) {
+	coll := session.DB(r.db).C(MIGRATIONS_COLLECTION)
+
+	var records []migrationRecord
+	if err := coll.Find(nil).All(&records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]bool, len(records))
+	for _, rec := range records {
+		applied[rec.Id] = true
+	}
+
+	return applied, nil
+}
+
+//TAKE OUT THE ADVISORY LOCK
+//relies on the lock document's _id being unique so only one runner can insert it at a time
+func (r *Runner) lock(session *mgo.Session) error {
+	coll := session.DB(r.db).C(MIGRATIONS_COLLECTION)
+
+	err := coll.Insert(bson.M{"_id": MIGRATIONS_LOCK_ID})
+	if mgo.IsDup(err) {
+		return ErrMigrationLocked
+	}
+
+	return err
+}
+
+//RELEASE THE ADVISORY LOCK
+func (r *Runner) unlock(session *mgo.Session) error {
+	coll := session.DB(r.db).C(MIGRATIONS_COLLECTION)
+
+	return coll.RemoveId(MIGRATIONS_LOCK_ID)
+}
@@ -56,26 +56,24 @@ var (
 //readPreference is an mgo consistency constant (Eventual, Monotonic, Strong)
 //writeConcern is an mgo *Safe type
 //saves the connected session pool to a global variable.
+//this is a thin wrapper around ConnectWithConfig kept for backwards compatibility, use ConnectWithConfig for
+//URI strings, TLS, auth mechanisms, timeouts, and pool sizing.
 func Connect(servers string, database string, readPreference int, writeConcern *mgo.Safe) {
 	//connection uri
 	uri := servers + database
 
 	//connect to db
-	session, err := mgo.Dial(uri)
+	session, err := ConnectWithConfig(Config{
+		URI:            uri,
+		ReadPreference: readPreference,
+		WriteConcern:   writeConcern,
+	})
 	if err != nil {
 		log.Println("mongoutils.go-Connect error")
 		log.Panicln(err)
 		return
 	}
 
-	//set db consistency
-	//read preference
-	setReadPreference(session, readPreference)
-
-	//set safety mode
-	//write concern
-	session.SetSafe(writeConcern)
-
 	//store session in global variable
 	//access this session by importing this file
 	log.Println("mongoutils.go-Connect okay")
@@ -0,0 +1,134 @@
+package mongoutils
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	PAGE_DEFAULT_VALUE      = 1
+	PAGE_SIZE_DEFAULT_VALUE = LIMIT_DEFAULT_VALUE
+)
+
+//QuerySpec HOLDS EVERYTHING NEEDED TO TURN AN HTTP LIST REQUEST INTO A CONSISTENT mgo.Query
+//build one with ParseQuery and apply it to a collection with Apply
+type QuerySpec struct {
+	Filter bson.M
+	Fields []string
+	Sort   []string
+	Skip   int
+	Limit  int
+}
+
+//PARSE FILTER, PROJECTION, SORT, AND PAGINATION GET VARIABLES INTO A QuerySpec
+//?filter= is JSON (or MongoDB extended JSON) decoded with bson.UnmarshalJSON, ex: ?filter={"status":"open"}
+//?fields= is a comma separated list of fields to project, a leading (-) minus sign excludes the field, ex: ?fields=name,email
+//mongo does not allow mixing inclusion and exclusion in one projection, so mixing them here (ex: ?fields=name,-password)
+//is treated as exclusion-only and any plain inclusion fields are ignored, see fieldsSelector
+//?sort= is handled the same way as the existing Sort() helper
+//?skip= sets an explicit offset, or ?page=&pageSize= compute one (page is 1-indexed)
+//?after=<objectId> switches to cursor-based pagination by adding {_id: {$gt: ObjectIdHex(after)}} to the filter, and
+//takes precedence over ?skip=/?page= since you cannot use both an offset and a cursor on the same query
+//in: http request
+//out: QuerySpec, error if filter or after could not be parsed
+func ParseQuery(r *http.Request) (QuerySpec, error) {
+	r.ParseForm()
+
+	spec := QuerySpec{
+		Filter: bson.M{},
+		Sort:   Sort(r),
+		Limit:  Limit(r),
+	}
+
+	if filter := r.FormValue("filter"); len(filter) > 0 {
+		if err := bson.UnmarshalJSON([]byte(filter), &spec.Filter); err != nil {
+			return spec, err
+		}
+	}
+
+	if fields := r.FormValue("fields"); len(fields) > 0 {
+		spec.Fields = strings.Split(fields, ",")
+	}
+
+	spec.Skip = parseSkip(r)
+
+	if after := r.FormValue("after"); len(after) > 0 {
+		id, err := GetObjectIdFromString(after)
+		if err != nil {
+			return spec, err
+		}
+
+		spec.Filter["_id"] = bson.M{"$gt": id}
+		spec.Skip = 0
+	}
+
+	return spec, nil
+}
+
+//FIGURE OUT THE OFFSET TO SKIP FROM ?skip= OR ?page=/?pageSize=
+//?skip= wins if both are given
+func parseSkip(r *http.Request) int {
+	if skip := r.FormValue("skip"); len(skip) > 0 {
+		if skipInt, err := strconv.Atoi(skip); err == nil {
+			return skipInt
+		}
+	}
+
+	page := PAGE_DEFAULT_VALUE
+	if p := r.FormValue("page"); len(p) > 0 {
+		if pInt, err := strconv.Atoi(p); err == nil && pInt > 0 {
+			page = pInt
+		}
+	}
+
+	pageSize := PAGE_SIZE_DEFAULT_VALUE
+	if ps := r.FormValue("pageSize"); len(ps) > 0 {
+		if psInt, err := strconv.Atoi(ps); err == nil && psInt > 0 {
+			pageSize = psInt
+		}
+	}
+
+	return (page - 1) * pageSize
+}
+
+//APPLY A QuerySpec TO A COLLECTION
+//chains Find().Sort().Skip().Limit().Select() so callers get consistent list semantics from one call
+//in: collection, QuerySpec (from ParseQuery)
+//out: an *mgo.Query ready to run with .All() or .One()
+func (spec QuerySpec) Apply(coll *mgo.Collection) *mgo.Query {
+	query := coll.Find(spec.Filter).Sort(spec.Sort...).Skip(spec.Skip).Limit(spec.Limit)
+
+	if len(spec.Fields) > 0 {
+		query = query.Select(fieldsSelector(spec.Fields))
+	}
+
+	return query
+}
+
+//BUILD A MONGO PROJECTION FROM ?fields= ENTRIES
+//mongo does not allow mixing inclusion and exclusion in the same projection (aside from _id), so if any field is
+//excluded (a "-" prefix) the whole selector is treated as exclusion-only and any plain inclusion fields are ignored
+func fieldsSelector(fields []string) bson.M {
+	exclude := false
+	for _, field := range fields {
+		if strings.HasPrefix(field, "-") {
+			exclude = true
+			break
+		}
+	}
+
+	selector := bson.M{}
+	for _, field := range fields {
+		if strings.HasPrefix(field, "-") {
+			selector[strings.TrimPrefix(field, "-")] = 0
+		} else if !exclude {
+			selector[field] = 1
+		}
+	}
+
+	return selector
+}